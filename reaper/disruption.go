@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// disruptionTargetReason mirrors the reason Kubernetes itself would record
+// on a DisruptionTarget condition, scoped to reaper-initiated disruptions.
+const disruptionTargetReason = "ReapedByPodReaper"
+
+type disruptionConditionPatch struct {
+	Status disruptionConditionPatchStatus `json:"status"`
+}
+
+type disruptionConditionPatchStatus struct {
+	Conditions []v1.PodCondition `json:"conditions"`
+}
+
+// buildDisruptionConditionPatch renders the strategic merge patch body that
+// stamps a DisruptionTarget=True condition onto a pod's status, with the
+// message set to the names of the rules that matched it.
+func buildDisruptionConditionPatch(ruleNames []string) ([]byte, error) {
+	patch := disruptionConditionPatch{
+		Status: disruptionConditionPatchStatus{
+			Conditions: []v1.PodCondition{{
+				Type:               v1.DisruptionTarget,
+				Status:             v1.ConditionTrue,
+				Reason:             disruptionTargetReason,
+				Message:            strings.Join(ruleNames, ", "),
+				LastTransitionTime: metav1.NewTime(time.Now()),
+			}},
+		},
+	}
+	return json.Marshal(patch)
+}
+
+// emitDisruptionTargetCondition patches the DisruptionTarget condition onto
+// the pod's status immediately before it is deleted or evicted. It is a
+// no-op under DRY_RUN so dry runs never mutate cluster state.
+func emitDisruptionTargetCondition(client kubernetes.Interface, pod v1.Pod, ruleNames []string, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+	patch, err := buildDisruptionConditionPatch(ruleNames)
+	if err != nil {
+		return err
+	}
+	_, err = client.CoreV1().Pods(pod.Namespace).Patch(
+		context.TODO(),
+		pod.Name,
+		types.StrategicMergePatchType,
+		patch,
+		metav1.PatchOptions{},
+		"status",
+	)
+	return err
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// evictionBackoffStep is the wait before the first retry of a PDB-blocked
+// eviction; the wait doubles after each further attempt, and retrying stops
+// once the next wait would run past the pod's EVICTION_TIMEOUT deadline.
+const evictionBackoffStep = 1 * time.Second
+
+// evictionOutcome records what happened to one pod's eviction, so the reap
+// loop can report a summary of pods skipped due to PDB pressure.
+type evictionOutcome struct {
+	pod          v1.Pod
+	err          error
+	skippedByPDB bool
+}
+
+// evictPods fans evictions for the given pods out across a worker pool sized
+// by MAX_CONCURRENT_EVICTIONS. When RESPECT_PDB is set, a pod whose eviction
+// is blocked by a PodDisruptionBudget (HTTP 429) is retried with bounded
+// exponential backoff until EVICTION_TIMEOUT elapses rather than failing
+// the run outright.
+func evictPods(client kubernetes.Interface, pods []v1.Pod, options options) []evictionOutcome {
+	workers := options.maxConcurrentEvictions
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make([]evictionOutcome, len(pods))
+
+	indexed := make(chan struct {
+		index int
+		pod   v1.Pod
+	})
+	go func() {
+		for i, pod := range pods {
+			indexed <- struct {
+				index int
+				pod   v1.Pod
+			}{i, pod}
+		}
+		close(indexed)
+	}()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range indexed {
+				outcome := evictPodWithBackoff(client, item.pod, options)
+				mu.Lock()
+				results[item.index] = outcome
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	logSkippedByPDB(results)
+	return results
+}
+
+func evictPodWithBackoff(client kubernetes.Interface, pod v1.Pod, options options) evictionOutcome {
+	eviction := &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+		},
+		DeleteOptions: &metav1.DeleteOptions{
+			GracePeriodSeconds: options.gracePeriod,
+		},
+	}
+
+	deadline := time.Now().Add(options.evictionTimeout)
+	delay := evictionBackoffStep
+	for {
+		err := client.PolicyV1().Evictions(pod.Namespace).Evict(context.TODO(), eviction)
+		if err == nil {
+			return evictionOutcome{pod: pod}
+		}
+		if !apierrors.IsTooManyRequests(err) || !options.respectPDB {
+			return evictionOutcome{pod: pod, err: err}
+		}
+		if time.Now().Add(delay).After(deadline) {
+			return evictionOutcome{pod: pod, err: err, skippedByPDB: true}
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+func logSkippedByPDB(outcomes []evictionOutcome) {
+	var skipped []string
+	for _, outcome := range outcomes {
+		if outcome.skippedByPDB {
+			skipped = append(skipped, fmt.Sprintf("%s/%s", outcome.pod.Namespace, outcome.pod.Name))
+		}
+	}
+	if len(skipped) > 0 {
+		log.Printf("skipped %d pod(s) due to PodDisruptionBudget pressure: %v", len(skipped), skipped)
+	}
+}
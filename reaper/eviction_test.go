@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func alwaysPDBBlockedReactor(action k8stesting.Action) (bool, runtime.Object, error) {
+	if action.GetSubresource() != "eviction" {
+		return false, nil, nil
+	}
+	return true, nil, apierrors.NewTooManyRequests("cannot evict pod as it would violate the pod's disruption budget", 1)
+}
+
+func TestEvictPodWithBackoffSkipsOnPDBTimeoutWhenRespected(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "pods", alwaysPDBBlockedReactor)
+
+	pod := v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}}
+	outcome := evictPodWithBackoff(client, pod, options{
+		respectPDB:      true,
+		evictionTimeout: time.Millisecond,
+	})
+
+	if !outcome.skippedByPDB {
+		t.Fatalf("expected eviction to be skipped due to PDB pressure, got %+v", outcome)
+	}
+}
+
+func TestEvictPodWithBackoffFailsImmediatelyWhenPDBNotRespected(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "pods", alwaysPDBBlockedReactor)
+
+	pod := v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}}
+	outcome := evictPodWithBackoff(client, pod, options{
+		respectPDB:      false,
+		evictionTimeout: time.Minute,
+	})
+
+	if outcome.skippedByPDB {
+		t.Fatal("expected eviction not to be marked as skipped when RESPECT_PDB is false")
+	}
+	if outcome.err == nil {
+		t.Fatal("expected the 429 to surface as an error when RESPECT_PDB is false")
+	}
+}
+
+func TestEvictPodWithBackoffSucceedsWithoutPDBPressure(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		return true, nil, nil
+	})
+
+	pod := v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}}
+	outcome := evictPodWithBackoff(client, pod, options{
+		respectPDB:      true,
+		evictionTimeout: time.Minute,
+	})
+
+	if outcome.err != nil || outcome.skippedByPDB {
+		t.Fatalf("expected a clean eviction, got %+v", outcome)
+	}
+}
+
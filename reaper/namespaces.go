@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// resolveNamespaces determines the concrete set of namespaces a single reap
+// run should operate against. NAMESPACE and NAMESPACES are combined as
+// explicit positive targeting; when neither is set, the namespace list is
+// resolved from the cluster via NAMESPACE_LABEL_SELECTOR (or, if that's also
+// unset, via an empty selector that matches every namespace) so that there is
+// always a concrete list to subtract EXCLUDE_NAMESPACES from — "all
+// namespaces except kube-system" only works once "all namespaces" is an
+// actual enumerated list rather than the empty-string List-everything
+// sentinel. Any namespace named in EXCLUDE_NAMESPACES is dropped from the
+// final set, whether it came from explicit targeting or the cluster listing.
+func resolveNamespaces(client kubernetes.Interface, options options) ([]string, error) {
+	resolved := map[string]bool{}
+
+	if options.namespace != "" {
+		resolved[options.namespace] = true
+	}
+	for _, ns := range options.namespaces {
+		if ns != "" {
+			resolved[ns] = true
+		}
+	}
+
+	if len(resolved) == 0 {
+		selector := options.namespaceLabelSelector
+		if selector == nil {
+			selector = labels.Everything()
+		}
+		list, err := client.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{
+			LabelSelector: selector.String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range list.Items {
+			resolved[ns.Name] = true
+		}
+	}
+
+	for _, excluded := range options.excludeNamespaces {
+		delete(resolved, excluded)
+	}
+
+	namespaces := make([]string, 0, len(resolved))
+	for ns := range resolved {
+		namespaces = append(namespaces, ns)
+	}
+	return namespaces, nil
+}
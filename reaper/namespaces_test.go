@@ -0,0 +1,71 @@
+package main
+
+import (
+	"sort"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newNamespace(name string, labelSet map[string]string) v1.Namespace {
+	return v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labelSet}}
+}
+
+func TestResolveNamespacesExcludesFromAllNamespacesFallback(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		newNamespace("default", nil),
+		newNamespace("kube-system", nil),
+		newNamespace("payments", nil),
+	)
+
+	resolved, err := resolveNamespaces(client, options{excludeNamespaces: []string{"kube-system"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	sort.Strings(resolved)
+	expected := []string{"default", "payments"}
+	if len(resolved) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, resolved)
+	}
+	for i := range expected {
+		if resolved[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, resolved)
+		}
+	}
+}
+
+func TestResolveNamespacesLabelSelectorMatchingNothingStaysEmpty(t *testing.T) {
+	client := fake.NewSimpleClientset(newNamespace("default", nil))
+
+	selector, err := labels.Parse("reaper=enabled")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	resolved, err := resolveNamespaces(client, options{namespaceLabelSelector: selector})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(resolved) != 0 {
+		t.Fatalf("expected no namespaces resolved, got %v", resolved)
+	}
+}
+
+func TestResolveNamespacesExplicitNamespaceIsExcludable(t *testing.T) {
+	client := fake.NewSimpleClientset()
+
+	resolved, err := resolveNamespaces(client, options{
+		namespace:         "kube-system",
+		excludeNamespaces: []string{"kube-system"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(resolved) != 0 {
+		t.Fatalf("expected no namespaces resolved, got %v", resolved)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// nodeTaint is one entry of NODE_TAINT_SELECTOR, e.g.
+// "node.kubernetes.io/unreachable:NoExecute".
+type nodeTaint struct {
+	key    string
+	effect v1.TaintEffect
+}
+
+// parseNodeTaintSelector parses a comma-separated "key:effect" list into the
+// taints a node must carry at least one of to match.
+func parseNodeTaintSelector(value string) ([]nodeTaint, error) {
+	var taints []nodeTaint
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("expected key:effect, got %q", entry)
+		}
+		taints = append(taints, nodeTaint{key: parts[0], effect: v1.TaintEffect(parts[1])})
+	}
+	return taints, nil
+}
+
+// nodeHasAnyTaint reports whether the node carries any of the selected taints.
+// An empty selector matches every node.
+func nodeHasAnyTaint(node v1.Node, selector []nodeTaint) bool {
+	if len(selector) == 0 {
+		return true
+	}
+	for _, taint := range node.Spec.Taints {
+		for _, wanted := range selector {
+			if taint.Key == wanted.key && taint.Effect == wanted.effect {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nodeCache lists nodes from the API server at most once per reap run and
+// hands the result to every rule that needs to restrict pods by node,
+// rather than re-listing nodes once per rule.
+type nodeCache struct {
+	client kubernetes.Interface
+
+	once  sync.Once
+	nodes []v1.Node
+	err   error
+}
+
+func newNodeCache(client kubernetes.Interface) *nodeCache {
+	return &nodeCache{client: client}
+}
+
+func (c *nodeCache) list(labelSelector labels.Selector, fieldSelector fields.Selector) ([]v1.Node, error) {
+	c.once.Do(func() {
+		list, err := c.client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{
+			LabelSelector: labelSelector.String(),
+			FieldSelector: fieldSelector.String(),
+		})
+		if err != nil {
+			c.err = err
+			return
+		}
+		c.nodes = list.Items
+	})
+	return c.nodes, c.err
+}
+
+// matchingNodeNames resolves the node label/field/taint selectors in options
+// to the set of node names pods must be scheduled on to match.
+func matchingNodeNames(cache *nodeCache, options options) ([]string, error) {
+	nodes, err := cache.list(options.nodeLabelSelector, options.nodeFieldSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, node := range nodes {
+		if nodeHasAnyTaint(node, options.nodeTaintSelector) {
+			names = append(names, node.Name)
+		}
+	}
+	return names, nil
+}
+
+// restrictToNodes filters pods down to those scheduled on one of nodeNames,
+// the client-side equivalent of a "spec.nodeName in (...)" field selector.
+func restrictToNodes(pods []v1.Pod, nodeNames []string) []v1.Pod {
+	allowed := make(map[string]bool, len(nodeNames))
+	for _, name := range nodeNames {
+		allowed[name] = true
+	}
+
+	restricted := make([]v1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if allowed[pod.Spec.NodeName] {
+			restricted = append(restricted, pod)
+		}
+	}
+	return restricted
+}
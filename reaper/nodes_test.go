@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestParseNodeTaintSelectorTrimsSpace(t *testing.T) {
+	taints, err := parseNodeTaintSelector("a:NoExecute, b:NoSchedule")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []nodeTaint{
+		{key: "a", effect: v1.TaintEffectNoExecute},
+		{key: "b", effect: v1.TaintEffectNoSchedule},
+	}
+	if len(taints) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, taints)
+	}
+	for i := range expected {
+		if taints[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, taints)
+		}
+	}
+}
+
+func TestParseNodeTaintSelectorRejectsMalformedEntry(t *testing.T) {
+	if _, err := parseNodeTaintSelector("missing-effect"); err == nil {
+		t.Fatal("expected an error for an entry without a key:effect separator")
+	}
+}
+
+func TestNodeHasAnyTaint(t *testing.T) {
+	node := v1.Node{Spec: v1.NodeSpec{Taints: []v1.Taint{
+		{Key: "node.kubernetes.io/unreachable", Effect: v1.TaintEffectNoExecute},
+	}}}
+
+	selector := []nodeTaint{{key: "node.kubernetes.io/unreachable", effect: v1.TaintEffectNoExecute}}
+	if !nodeHasAnyTaint(node, selector) {
+		t.Fatal("expected node to match its own taint")
+	}
+
+	selector = []nodeTaint{{key: "my.co/drain", effect: v1.TaintEffectNoSchedule}}
+	if nodeHasAnyTaint(node, selector) {
+		t.Fatal("expected node not to match an unrelated taint")
+	}
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"errors"
 	"fmt"
 	v1 "k8s.io/api/core/v1"
 	"math/rand"
@@ -11,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
 
@@ -19,6 +19,9 @@ import (
 
 // environment variable names
 const envNamespace = "NAMESPACE"
+const envNamespaces = "NAMESPACES"
+const envNamespaceLabelSelector = "NAMESPACE_LABEL_SELECTOR"
+const envExcludeNamespaces = "EXCLUDE_NAMESPACES"
 const envGracePeriod = "GRACE_PERIOD"
 const envScheduleCron = "SCHEDULE"
 const envRunDuration = "RUN_DURATION"
@@ -28,29 +31,79 @@ const envRequireLabelKey = "REQUIRE_LABEL_KEY"
 const envRequireLabelValues = "REQUIRE_LABEL_VALUES"
 const envRequireAnnotationKey = "REQUIRE_ANNOTATION_KEY"
 const envRequireAnnotationValues = "REQUIRE_ANNOTATION_VALUES"
+const envLabelSelector = "LABEL_SELECTOR"
+const envExcludeLabelSelector = "EXCLUDE_LABEL_SELECTOR"
+const envAnnotationSelector = "ANNOTATION_SELECTOR"
+const envFieldSelector = "FIELD_SELECTOR"
 const envDryRun = "DRY_RUN"
 const envMaxPods = "MAX_PODS"
 const envPodSortingStrategy = "POD_SORTING_STRATEGY"
 const envEvict = "EVICT"
+const envEmitDisruptionCondition = "EMIT_DISRUPTION_CONDITION"
+const envNodeLabelSelector = "NODE_LABEL_SELECTOR"
+const envNodeFieldSelector = "NODE_FIELD_SELECTOR"
+const envNodeTaintSelector = "NODE_TAINT_SELECTOR"
+const envRespectPDB = "RESPECT_PDB"
+const envMaxConcurrentEvictions = "MAX_CONCURRENT_EVICTIONS"
+const envEvictionTimeout = "EVICTION_TIMEOUT"
 
 type options struct {
-	namespace             string
-	gracePeriod           *int64
-	schedule              string
-	runDuration           time.Duration
-	labelExclusion        *labels.Requirement
-	labelRequirement      *labels.Requirement
-	annotationRequirement *labels.Requirement
-	dryRun                bool
-	maxPods               int
-	rules                 rules.Rules
-	evict                 bool
+	namespace               string
+	namespaces              []string
+	namespaceLabelSelector  labels.Selector
+	excludeNamespaces       []string
+	gracePeriod             *int64
+	schedule                string
+	runDuration             time.Duration
+	labelSelector           labels.Selector
+	excludeLabelSelector    labels.Selector
+	annotationSelector      labels.Selector
+	fieldSelector           fields.Selector
+	dryRun                  bool
+	maxPods                 int
+	rules                   rules.Rules
+	evict                   bool
+	emitDisruptionCondition bool
+	nodeLabelSelector       labels.Selector
+	nodeFieldSelector       fields.Selector
+	nodeTaintSelector       []nodeTaint
+	respectPDB              bool
+	maxConcurrentEvictions  int
+	evictionTimeout         time.Duration
 }
 
 func namespace() string {
 	return os.Getenv(envNamespace)
 }
 
+func namespaces() []string {
+	value, exists := os.LookupEnv(envNamespaces)
+	if !exists {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+func namespaceLabelSelector() (labels.Selector, error) {
+	selectorExpression, exists := os.LookupEnv(envNamespaceLabelSelector)
+	if !exists {
+		return labels.Everything(), nil
+	}
+	selector, err := labels.Parse(selectorExpression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", envNamespaceLabelSelector, err)
+	}
+	return selector, nil
+}
+
+func excludeNamespaces() []string {
+	value, exists := os.LookupEnv(envExcludeNamespaces)
+	if !exists {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
 func gracePeriod() (*int64, error) {
 	envGraceDuration, exists := os.LookupEnv(envGracePeriod)
 	if !exists {
@@ -88,58 +141,122 @@ func runDuration() (time.Duration, error) {
 	return envDuration(envRunDuration, "0s")
 }
 
-func labelExclusion() (*labels.Requirement, error) {
-	labelKey, labelKeyExists := os.LookupEnv(envExcludeLabelKey)
-	labelValue, labelValuesExist := os.LookupEnv(envExcludeLabelValues)
-	if labelKeyExists && !labelValuesExist {
-		return nil, fmt.Errorf("specified %s but not %s", envExcludeLabelKey, envExcludeLabelValues)
-	} else if !labelKeyExists && labelValuesExist {
-		return nil, fmt.Errorf("did not specify %s but did specify %s", envExcludeLabelKey, envExcludeLabelValues)
-	} else if !labelKeyExists && !labelValuesExist {
+// deprecatedKeyValueRequirement builds a single requirement from the legacy
+// "key" + "comma-separated values" pair of env vars, for folding into a
+// selector alongside whatever the new *_SELECTOR env var specifies.
+func deprecatedKeyValueRequirement(keyEnv string, valuesEnv string, op selection.Operator) (*labels.Requirement, error) {
+	key, keyExists := os.LookupEnv(keyEnv)
+	value, valuesExist := os.LookupEnv(valuesEnv)
+	if keyExists && !valuesExist {
+		return nil, fmt.Errorf("specified %s but not %s", keyEnv, valuesEnv)
+	} else if !keyExists && valuesExist {
+		return nil, fmt.Errorf("did not specify %s but did specify %s", keyEnv, valuesEnv)
+	} else if !keyExists && !valuesExist {
 		return nil, nil
 	}
-	labelValues := strings.Split(labelValue, ",")
-	labelExclusion, err := labels.NewRequirement(labelKey, selection.NotIn, labelValues)
+	values := strings.Split(value, ",")
+	requirement, err := labels.NewRequirement(key, op, values)
 	if err != nil {
-		return nil, fmt.Errorf("could not create exclusion label: %s", err)
+		return nil, fmt.Errorf("could not create requirement for %s: %s", keyEnv, err)
 	}
-	return labelExclusion, nil
+	return requirement, nil
 }
 
-func labelRequirement() (*labels.Requirement, error) {
-	labelKey, labelKeyExists := os.LookupEnv(envRequireLabelKey)
-	labelValue, labelValuesExist := os.LookupEnv(envRequireLabelValues)
-	if labelKeyExists && !labelValuesExist {
-		return nil, fmt.Errorf("specified %s but not %s", envRequireLabelKey, envRequireLabelValues)
-	} else if !labelKeyExists && labelValuesExist {
-		return nil, fmt.Errorf("did not specify %s but did specify %s", envRequireLabelKey, envRequireLabelValues)
-	} else if !labelKeyExists && !labelValuesExist {
-		return nil, nil
+// selectorWithDeprecatedRequirement parses the selectorEnv expression (if
+// set) and folds in the requirement built from the deprecated key/values
+// pair (if set), so that existing configurations keep working unchanged.
+func selectorWithDeprecatedRequirement(selectorEnv string, deprecated *labels.Requirement) (labels.Selector, error) {
+	selectorExpression, exists := os.LookupEnv(selectorEnv)
+	selector := labels.NewSelector()
+	if exists {
+		parsed, err := labels.Parse(selectorExpression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", selectorEnv, err)
+		}
+		requirements, _ := parsed.Requirements()
+		selector = selector.Add(requirements...)
+	}
+	if deprecated != nil {
+		selector = selector.Add(*deprecated)
+	}
+	return selector, nil
+}
+
+func labelSelector() (labels.Selector, error) {
+	deprecated, err := deprecatedKeyValueRequirement(envRequireLabelKey, envRequireLabelValues, selection.In)
+	if err != nil {
+		return nil, err
+	}
+	return selectorWithDeprecatedRequirement(envLabelSelector, deprecated)
+}
+
+// excludeLabelSelector returns a positive "pods matching this selector are
+// excluded" matcher, consistent with how EXCLUDE_LABEL_SELECTOR expressions
+// parse. The deprecated EXCLUDE_LABEL_KEY/VALUES pair historically built a
+// NotIn requirement (labels to keep), so it's inverted to In (labels to
+// exclude) here to fold into the same polarity.
+func excludeLabelSelector() (labels.Selector, error) {
+	deprecated, err := deprecatedKeyValueRequirement(envExcludeLabelKey, envExcludeLabelValues, selection.In)
+	if err != nil {
+		return nil, err
+	}
+	return selectorWithDeprecatedRequirement(envExcludeLabelSelector, deprecated)
+}
+
+func annotationSelector() (labels.Selector, error) {
+	deprecated, err := deprecatedKeyValueRequirement(envRequireAnnotationKey, envRequireAnnotationValues, selection.In)
+	if err != nil {
+		return nil, err
+	}
+	return selectorWithDeprecatedRequirement(envAnnotationSelector, deprecated)
+}
+
+func fieldSelector() (fields.Selector, error) {
+	selectorExpression, exists := os.LookupEnv(envFieldSelector)
+	if !exists {
+		return fields.Everything(), nil
+	}
+	selector, err := fields.ParseSelector(selectorExpression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", envFieldSelector, err)
+	}
+	return selector, nil
+}
+
+func nodeLabelSelector() (labels.Selector, error) {
+	selectorExpression, exists := os.LookupEnv(envNodeLabelSelector)
+	if !exists {
+		return labels.Everything(), nil
+	}
+	selector, err := labels.Parse(selectorExpression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", envNodeLabelSelector, err)
+	}
+	return selector, nil
+}
+
+func nodeFieldSelector() (fields.Selector, error) {
+	selectorExpression, exists := os.LookupEnv(envNodeFieldSelector)
+	if !exists {
+		return fields.Everything(), nil
 	}
-	labelValues := strings.Split(labelValue, ",")
-	labelRequirement, err := labels.NewRequirement(labelKey, selection.In, labelValues)
+	selector, err := fields.ParseSelector(selectorExpression)
 	if err != nil {
-		return nil, fmt.Errorf("could not create requirement label: %s", err)
+		return nil, fmt.Errorf("invalid %s: %s", envNodeFieldSelector, err)
 	}
-	return labelRequirement, nil
+	return selector, nil
 }
 
-func annotationRequirement() (*labels.Requirement, error) {
-	annotationKey, annotationKeyExists := os.LookupEnv(envRequireAnnotationKey)
-	annotationValue, annotationValuesExist := os.LookupEnv(envRequireAnnotationValues)
-	if annotationKeyExists && !annotationValuesExist {
-		return nil, fmt.Errorf("specified %s but not %s", envRequireAnnotationKey, envRequireAnnotationValues)
-	} else if !annotationKeyExists && annotationValuesExist {
-		return nil, fmt.Errorf("did not specify %s but did specify %s", envRequireAnnotationKey, envRequireAnnotationValues)
-	} else if !annotationKeyExists && !annotationValuesExist {
+func nodeTaintSelector() ([]nodeTaint, error) {
+	value, exists := os.LookupEnv(envNodeTaintSelector)
+	if !exists {
 		return nil, nil
 	}
-	annotationValues := strings.Split(annotationValue, ",")
-	annotationRequirement, err := labels.NewRequirement(annotationKey, selection.In, annotationValues)
+	taints, err := parseNodeTaintSelector(value)
 	if err != nil {
-		return nil, fmt.Errorf("could not create annotation requirement: %s", err)
+		return nil, fmt.Errorf("invalid %s: %s", envNodeTaintSelector, err)
 	}
-	return annotationRequirement, nil
+	return taints, nil
 }
 
 func dryRun() (bool, error) {
@@ -179,20 +296,103 @@ func getPodDeletionCost(pod v1.Pod) int32 {
 	return int32(cost)
 }
 
-func podSortingStrategy() (func([]v1.Pod), error) {
-	sortingStrategy, present := os.LookupEnv(envPodSortingStrategy)
-	if !present {
-		return func(pods []v1.Pod) {}, nil
+func podPriority(pod v1.Pod) int32 {
+	if pod.Spec.Priority == nil {
+		return 0
 	}
+	return *pod.Spec.Priority
+}
+
+// qosRank orders QoS classes the way the kubelet does when it evicts pods
+// under resource pressure: BestEffort pods go first, then Burstable, then
+// Guaranteed pods last.
+func qosRank(pod v1.Pod) int {
+	switch pod.Status.QOSClass {
+	case v1.PodQOSBestEffort:
+		return 0
+	case v1.PodQOSBurstable:
+		return 1
+	case v1.PodQOSGuaranteed:
+		return 2
+	default:
+		return 0
+	}
+}
+
+func podReady(pod v1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func podRestartCount(pod v1.Pod) int32 {
+	var restarts int32
+	for _, status := range pod.Status.ContainerStatuses {
+		restarts += status.RestartCount
+	}
+	return restarts
+}
+
+// activePodPhaseRank mirrors the phase precedence controller.ActivePods
+// uses when ranking ReplicaSet replicas for scale-down: unknown phases sort
+// after Pending but before a pod that has actually started Running.
+func activePodPhaseRank(pod v1.Pod) int {
+	switch pod.Status.Phase {
+	case v1.PodPending:
+		return 0
+	case v1.PodUnknown:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// activePodsLess reimplements controller.ActivePods' ranking: unassigned
+// pods before assigned, Pending before Unknown before Running, not-ready
+// before ready, higher restart count first, and newer before older. Pods
+// ranked "less" here are the least useful replicas and get reaped first.
+func activePodsLess(a v1.Pod, b v1.Pod) bool {
+	aAssigned := a.Spec.NodeName != ""
+	bAssigned := b.Spec.NodeName != ""
+	if aAssigned != bAssigned {
+		return !aAssigned
+	}
+
+	aPhase, bPhase := activePodPhaseRank(a), activePodPhaseRank(b)
+	if aPhase != bPhase {
+		return aPhase < bPhase
+	}
+
+	aReady, bReady := podReady(a), podReady(b)
+	if aReady != bReady {
+		return !aReady
+	}
+
+	aRestarts, bRestarts := podRestartCount(a), podRestartCount(b)
+	if aRestarts != bRestarts {
+		return aRestarts > bRestarts
+	}
+
+	if a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return false
+	}
+	return b.CreationTimestamp.Before(&a.CreationTimestamp)
+}
 
-	switch sortingStrategy {
+// singleSortingStrategy resolves one POD_SORTING_STRATEGY term to the sort
+// function it names.
+func singleSortingStrategy(name string) (func([]v1.Pod), error) {
+	switch name {
 	case "random":
 		return func(pods []v1.Pod) {
 			rand.Shuffle(len(pods), func(i, j int) { pods[i], pods[j] = pods[j], pods[i] })
 		}, nil
 	case "oldest-first":
 		return func(pods []v1.Pod) {
-			sort.Slice(pods, func(i, j int) bool {
+			sort.SliceStable(pods, func(i, j int) bool {
 				if pods[i].Status.StartTime == nil {
 					return false
 				}
@@ -204,7 +404,7 @@ func podSortingStrategy() (func([]v1.Pod), error) {
 		}, nil
 	case "youngest-first":
 		return func(pods []v1.Pod) {
-			sort.Slice(pods, func(i, j int) bool {
+			sort.SliceStable(pods, func(i, j int) bool {
 				if pods[i].Status.StartTime == nil {
 					return false
 				}
@@ -216,15 +416,67 @@ func podSortingStrategy() (func([]v1.Pod), error) {
 		}, nil
 	case "pod-deletion-cost":
 		return func(pods []v1.Pod) {
-			sort.Slice(pods, func(i, j int) bool {
+			sort.SliceStable(pods, func(i, j int) bool {
 				return getPodDeletionCost(pods[i]) < getPodDeletionCost(pods[j])
 			})
 		}, nil
+	case "priority-asc":
+		return func(pods []v1.Pod) {
+			sort.SliceStable(pods, func(i, j int) bool {
+				return podPriority(pods[i]) < podPriority(pods[j])
+			})
+		}, nil
+	case "priority-desc":
+		return func(pods []v1.Pod) {
+			sort.SliceStable(pods, func(i, j int) bool {
+				return podPriority(pods[i]) > podPriority(pods[j])
+			})
+		}, nil
+	case "qos-best-effort-first":
+		return func(pods []v1.Pod) {
+			sort.SliceStable(pods, func(i, j int) bool {
+				return qosRank(pods[i]) < qosRank(pods[j])
+			})
+		}, nil
+	case "active-pods":
+		return func(pods []v1.Pod) {
+			sort.SliceStable(pods, func(i, j int) bool {
+				return activePodsLess(pods[i], pods[j])
+			})
+		}, nil
 	default:
-		return nil, errors.New("unknown pod sorting strategy")
+		return nil, fmt.Errorf("unknown pod sorting strategy: %s", name)
 	}
 }
 
+// podSortingStrategy builds the composite sort function for POD_SORTING_STRATEGY.
+// Strategies may be chained as a comma-separated list; each is a stable sort
+// applied right-to-left, so the leftmost strategy takes precedence and later
+// ones only break ties it leaves behind. "random" is a shuffle rather than a
+// stable sort, so any strategy to its right has no effect on the result.
+func podSortingStrategy() (func([]v1.Pod), error) {
+	value, present := os.LookupEnv(envPodSortingStrategy)
+	if !present {
+		return func(pods []v1.Pod) {}, nil
+	}
+
+	names := strings.Split(value, ",")
+	strategies := make([]func([]v1.Pod), len(names))
+	for i, name := range names {
+		strategy, err := singleSortingStrategy(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		strategies[i] = strategy
+	}
+
+	return func(pods []v1.Pod) {
+		for i := len(strategies) - 1; i >= 0; i-- {
+			strategies[i](pods)
+		}
+	}, nil
+}
+
 func evict() (bool, error) {
 	value, exists := os.LookupEnv(envEvict)
 	if !exists {
@@ -233,8 +485,52 @@ func evict() (bool, error) {
 	return strconv.ParseBool(value)
 }
 
+func respectPDB() (bool, error) {
+	value, exists := os.LookupEnv(envRespectPDB)
+	if !exists {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+func maxConcurrentEvictions() (int, error) {
+	value, exists := os.LookupEnv(envMaxConcurrentEvictions)
+	if !exists {
+		return 1, nil
+	}
+
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if v < 1 {
+		return 1, nil
+	}
+
+	return v, nil
+}
+
+func evictionTimeout() (time.Duration, error) {
+	return envDuration(envEvictionTimeout, "30s")
+}
+
+func emitDisruptionCondition() (bool, error) {
+	value, exists := os.LookupEnv(envEmitDisruptionCondition)
+	if !exists {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
 func loadOptions() (options options, err error) {
 	options.namespace = namespace()
+	options.namespaces = namespaces()
+	options.namespaceLabelSelector, err = namespaceLabelSelector()
+	if err != nil {
+		return options, err
+	}
+	options.excludeNamespaces = excludeNamespaces()
 	options.gracePeriod, err = gracePeriod()
 	if err != nil {
 		return options, err
@@ -244,15 +540,19 @@ func loadOptions() (options options, err error) {
 	if err != nil {
 		return options, err
 	}
-	options.labelExclusion, err = labelExclusion()
+	options.labelSelector, err = labelSelector()
+	if err != nil {
+		return options, err
+	}
+	options.excludeLabelSelector, err = excludeLabelSelector()
 	if err != nil {
 		return options, err
 	}
-	options.labelRequirement, err = labelRequirement()
+	options.annotationSelector, err = annotationSelector()
 	if err != nil {
 		return options, err
 	}
-	options.annotationRequirement, err = annotationRequirement()
+	options.fieldSelector, err = fieldSelector()
 	if err != nil {
 		return options, err
 	}
@@ -270,6 +570,37 @@ func loadOptions() (options options, err error) {
 		return options, err
 	}
 
+	options.emitDisruptionCondition, err = emitDisruptionCondition()
+	if err != nil {
+		return options, err
+	}
+
+	options.nodeLabelSelector, err = nodeLabelSelector()
+	if err != nil {
+		return options, err
+	}
+	options.nodeFieldSelector, err = nodeFieldSelector()
+	if err != nil {
+		return options, err
+	}
+	options.nodeTaintSelector, err = nodeTaintSelector()
+	if err != nil {
+		return options, err
+	}
+
+	options.respectPDB, err = respectPDB()
+	if err != nil {
+		return options, err
+	}
+	options.maxConcurrentEvictions, err = maxConcurrentEvictions()
+	if err != nil {
+		return options, err
+	}
+	options.evictionTimeout, err = evictionTimeout()
+	if err != nil {
+		return options, err
+	}
+
 	// rules
 	options.rules, err = rules.LoadRules()
 	if err != nil {
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestActivePodsLessUnassignedBeforeAssigned(t *testing.T) {
+	unassigned := v1.Pod{}
+	assigned := v1.Pod{Spec: v1.PodSpec{NodeName: "node-1"}}
+	if !activePodsLess(unassigned, assigned) {
+		t.Fatal("expected unassigned pod to be less than assigned pod")
+	}
+	if activePodsLess(assigned, unassigned) {
+		t.Fatal("expected assigned pod not to be less than unassigned pod")
+	}
+}
+
+func TestActivePodsLessPhaseOrdering(t *testing.T) {
+	pending := v1.Pod{Status: v1.PodStatus{Phase: v1.PodPending}}
+	unknown := v1.Pod{Status: v1.PodStatus{Phase: v1.PodUnknown}}
+	running := v1.Pod{Status: v1.PodStatus{Phase: v1.PodRunning}}
+
+	if !activePodsLess(pending, unknown) {
+		t.Fatal("expected Pending before Unknown")
+	}
+	if !activePodsLess(unknown, running) {
+		t.Fatal("expected Unknown before Running")
+	}
+}
+
+func TestActivePodsLessNotReadyBeforeReady(t *testing.T) {
+	notReady := v1.Pod{}
+	ready := v1.Pod{Status: v1.PodStatus{Conditions: []v1.PodCondition{
+		{Type: v1.PodReady, Status: v1.ConditionTrue},
+	}}}
+	if !activePodsLess(notReady, ready) {
+		t.Fatal("expected not-ready pod to be less than ready pod")
+	}
+}
+
+func TestActivePodsLessHigherRestartCountFirst(t *testing.T) {
+	fewRestarts := v1.Pod{Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{{RestartCount: 1}}}}
+	manyRestarts := v1.Pod{Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{{RestartCount: 5}}}}
+	if !activePodsLess(manyRestarts, fewRestarts) {
+		t.Fatal("expected the pod with more restarts to sort first")
+	}
+}
+
+func TestActivePodsLessNewerBeforeOlder(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	older := v1.Pod{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))}}
+	newer := v1.Pod{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(now)}}
+	if !activePodsLess(newer, older) {
+		t.Fatal("expected the newer pod to sort before the older pod")
+	}
+}
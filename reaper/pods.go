@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// listPods fetches the candidate pods for a namespace. LABEL_SELECTOR and
+// FIELD_SELECTOR (and their deprecated shims) are applied server-side so the
+// API server does the filtering instead of the reaper paging through every
+// pod; EXCLUDE_LABEL_SELECTOR and ANNOTATION_SELECTOR are then applied
+// client-side, since an arbitrary exclusion expression and annotation
+// matching have no server-side equivalent in the pod List API.
+func listPods(client kubernetes.Interface, namespace string, options options) ([]v1.Pod, error) {
+	list, err := client.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: options.labelSelector.String(),
+		FieldSelector: options.fieldSelector.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pods := make([]v1.Pod, 0, len(list.Items))
+	for _, pod := range list.Items {
+		if selectorConfigured(options.excludeLabelSelector) && options.excludeLabelSelector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		if selectorConfigured(options.annotationSelector) && !options.annotationSelector.Matches(labels.Set(pod.Annotations)) {
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	return pods, nil
+}
+
+// selectorConfigured guards against the empty-selector trap: an unset
+// selector defaults to labels.NewSelector(), whose Matches is vacuously true
+// for every label set, so callers must check this before treating a match
+// as meaningful (e.g. "exclude if matches" would otherwise exclude every pod).
+func selectorConfigured(selector labels.Selector) bool {
+	return selector != nil && !selector.Empty()
+}